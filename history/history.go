@@ -0,0 +1,87 @@
+// Package history retains a rolling window of polled metric samples so the
+// TUI can render trends (e.g. sparklines) instead of only a point-in-time
+// value. Every poll used to discard the previous state entirely; Store lets
+// callers keep the last window's worth of it instead.
+package history
+
+import (
+	"sync"
+	"time"
+)
+
+// Key identifies a single time series: one metric for one executor of one
+// application.
+type Key struct {
+	AppId      string
+	ExecutorId string
+	Metric     string
+}
+
+type sample struct {
+	at    time.Time
+	value float64
+}
+
+// Store retains, per Key, the samples recorded within the last window of
+// time. It is safe for concurrent use.
+type Store struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	samples map[Key][]sample
+}
+
+// NewStore builds a Store that retains samples for up to window.
+func NewStore(window time.Duration) *Store {
+	return &Store{
+		window:  window,
+		samples: make(map[Key][]sample),
+	}
+}
+
+// Record appends value, observed now, to key's series, and prunes any
+// samples older than the retention window.
+func (s *Store) Record(key Key, value float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	series := append(s.samples[key], sample{at: now, value: value})
+
+	cutoff := now.Add(-s.window)
+	firstLive := len(series)
+	for i, sm := range series {
+		if sm.at.After(cutoff) {
+			firstLive = i
+			break
+		}
+	}
+	s.samples[key] = series[firstLive:]
+}
+
+// ForgetApp drops every series belonging to appId, e.g. once its
+// application has disappeared from polled state.
+func (s *Store) ForgetApp(appId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.samples {
+		if key.AppId == appId {
+			delete(s.samples, key)
+		}
+	}
+}
+
+// Series returns the retained values for key, oldest first, suitable for
+// feeding directly into a termui Sparkline.
+func (s *Store) Series(key Key) []float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	series := s.samples[key]
+	values := make([]float64, len(series))
+	for i, sm := range series {
+		values[i] = sm.value
+	}
+	return values
+}
@@ -0,0 +1,58 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStoreRecordPrunesSamplesOutsideWindow(t *testing.T) {
+	s := NewStore(30 * time.Millisecond)
+	key := Key{AppId: "app-1", ExecutorId: "1", Metric: "cpu_time_ms"}
+
+	s.Record(key, 1)
+	time.Sleep(50 * time.Millisecond)
+	s.Record(key, 2)
+
+	got := s.Series(key)
+	if len(got) != 1 || got[0] != 2 {
+		t.Fatalf("Series() = %v, want [2] (the first sample should have aged out)", got)
+	}
+}
+
+func TestStoreRecordRetainsSamplesWithinWindow(t *testing.T) {
+	s := NewStore(time.Minute)
+	key := Key{AppId: "app-1", ExecutorId: "1", Metric: "cpu_time_ms"}
+
+	s.Record(key, 1)
+	s.Record(key, 2)
+	s.Record(key, 3)
+
+	got := s.Series(key)
+	want := []float64{1, 2, 3}
+	if len(got) != len(want) {
+		t.Fatalf("Series() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Series() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestStoreForgetAppDropsOnlyThatAppsSeries(t *testing.T) {
+	s := NewStore(time.Minute)
+	keyApp1 := Key{AppId: "app-1", ExecutorId: "1", Metric: "cpu_time_ms"}
+	keyApp2 := Key{AppId: "app-2", ExecutorId: "1", Metric: "cpu_time_ms"}
+
+	s.Record(keyApp1, 1)
+	s.Record(keyApp2, 2)
+
+	s.ForgetApp("app-1")
+
+	if got := s.Series(keyApp1); len(got) != 0 {
+		t.Errorf("Series(app-1) = %v after ForgetApp, want empty", got)
+	}
+	if got := s.Series(keyApp2); len(got) != 1 || got[0] != 2 {
+		t.Errorf("Series(app-2) = %v after ForgetApp(app-1), want [2]", got)
+	}
+}
@@ -0,0 +1,159 @@
+// Package metrics exposes poller.Snapshot state as Prometheus metrics,
+// registering gauges per app/job/stage as they appear and
+// unregistering them once the app disappears from the snapshot.
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/Emberwalker/sparkmon/poller"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "sparkmon"
+
+// Registry tracks the set of per-app/job/stage metrics currently registered
+// with Prometheus, so it can add new ones and drop stale ones on each
+// Update.
+type Registry struct {
+	reg *prometheus.Registry
+
+	stageTasksActive    *prometheus.GaugeVec
+	stageTasksCompleted *prometheus.GaugeVec
+	stageTasksFailed    *prometheus.GaugeVec
+	stageTasksKilled    *prometheus.GaugeVec
+	stageTasksTotal     *prometheus.GaugeVec
+	stageDuration       *prometheus.GaugeVec
+	jobStatus           *prometheus.GaugeVec
+
+	// known tracks the label sets currently registered, keyed by appId, so
+	// Update can detect apps that have disappeared between polls.
+	known map[string]bool
+}
+
+// NewRegistry builds a Registry with all metric vectors registered against
+// a fresh prometheus.Registry.
+func NewRegistry() *Registry {
+	r := &Registry{
+		reg: prometheus.NewRegistry(),
+		stageTasksActive: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "stage_tasks_active",
+			Help:      "Number of currently active tasks in a stage.",
+		}, []string{"app_id", "app_name", "job_id", "stage_id"}),
+		stageTasksCompleted: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "stage_tasks_completed",
+			Help:      "Number of completed tasks in a stage.",
+		}, []string{"app_id", "app_name", "job_id", "stage_id"}),
+		stageTasksFailed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "stage_tasks_failed",
+			Help:      "Number of failed tasks in a stage.",
+		}, []string{"app_id", "app_name", "job_id", "stage_id"}),
+		stageTasksKilled: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "stage_tasks_killed",
+			Help:      "Number of killed tasks in a stage.",
+		}, []string{"app_id", "app_name", "job_id", "stage_id"}),
+		stageTasksTotal: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "stage_tasks_total",
+			Help:      "Total number of tasks in a stage.",
+		}, []string{"app_id", "app_name", "job_id", "stage_id"}),
+		stageDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "stage_duration_seconds",
+			Help:      "Wall-clock duration of a completed stage, in seconds.",
+		}, []string{"app_id", "app_name", "job_id", "stage_id"}),
+		jobStatus: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "job_status",
+			Help:      "Job status as a numeric code (1 = running, 2 = succeeded, 3 = failed, 0 = unknown).",
+		}, []string{"app_id", "app_name", "job_id", "job_name"}),
+		known: make(map[string]bool),
+	}
+
+	r.reg.MustRegister(
+		r.stageTasksActive,
+		r.stageTasksCompleted,
+		r.stageTasksFailed,
+		r.stageTasksKilled,
+		r.stageTasksTotal,
+		r.stageDuration,
+		r.jobStatus,
+	)
+
+	return r
+}
+
+// Registerer returns the underlying prometheus.Registry so callers can wire
+// it into an http.Handler (e.g. via promhttp.HandlerFor).
+func (r *Registry) Registerer() *prometheus.Registry {
+	return r.reg
+}
+
+// Update refreshes all metrics from snapshot, adding label sets for newly
+// seen apps/jobs/stages and removing label sets for apps that are no longer
+// present.
+func (r *Registry) Update(snapshot poller.Snapshot) {
+	seen := make(map[string]bool)
+
+	for _, app := range snapshot.Apps {
+		seen[app.App.Id] = true
+		r.known[app.App.Id] = true
+
+		for _, job := range app.Jobs {
+			jobLabels := prometheus.Labels{
+				"app_id":   app.App.Id,
+				"app_name": app.App.Name,
+				"job_id":   strconv.Itoa(job.Job.Index),
+				"job_name": job.Job.Name,
+			}
+			r.jobStatus.With(jobLabels).Set(jobStatusCode(job.Job.Status))
+
+			for _, stage := range job.Stages {
+				stageLabels := prometheus.Labels{
+					"app_id":   app.App.Id,
+					"app_name": app.App.Name,
+					"job_id":   strconv.Itoa(job.Job.Index),
+					"stage_id": strconv.Itoa(stage.Index),
+				}
+				r.stageTasksActive.With(stageLabels).Set(float64(stage.ActiveTasks))
+				r.stageTasksCompleted.With(stageLabels).Set(float64(stage.CompletedTasks))
+				r.stageTasksFailed.With(stageLabels).Set(float64(stage.FailedTasks))
+				r.stageTasksKilled.With(stageLabels).Set(float64(stage.KilledTasks))
+				r.stageTasksTotal.With(stageLabels).Set(float64(stage.Tasks))
+				if duration, ok := stage.Duration(); ok {
+					r.stageDuration.With(stageLabels).Set(duration.Seconds())
+				}
+			}
+		}
+	}
+
+	for appId := range r.known {
+		if !seen[appId] {
+			r.stageTasksActive.DeletePartialMatch(prometheus.Labels{"app_id": appId})
+			r.stageTasksCompleted.DeletePartialMatch(prometheus.Labels{"app_id": appId})
+			r.stageTasksFailed.DeletePartialMatch(prometheus.Labels{"app_id": appId})
+			r.stageTasksKilled.DeletePartialMatch(prometheus.Labels{"app_id": appId})
+			r.stageTasksTotal.DeletePartialMatch(prometheus.Labels{"app_id": appId})
+			r.stageDuration.DeletePartialMatch(prometheus.Labels{"app_id": appId})
+			r.jobStatus.DeletePartialMatch(prometheus.Labels{"app_id": appId})
+			delete(r.known, appId)
+		}
+	}
+}
+
+func jobStatusCode(status string) float64 {
+	switch status {
+	case "RUNNING":
+		return 1
+	case "SUCCEEDED":
+		return 2
+	case "FAILED":
+		return 3
+	default:
+		return 0
+	}
+}
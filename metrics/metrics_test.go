@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/Emberwalker/sparkmon/poller"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func snapshotWithApp(appId string) poller.Snapshot {
+	return poller.Snapshot{
+		Apps: []poller.EnrichedApplication{
+			{
+				App: poller.Application{Id: appId, Name: "Test App"},
+				Jobs: []poller.EnrichedJob{
+					{
+						Job: poller.Job{Index: 0, Name: "job-0", Status: "RUNNING"},
+						Stages: []poller.Stage{
+							{Index: 0, Tasks: 4, ActiveTasks: 2, CompletedTasks: 1},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRegistryUpdateRegistersNewApp(t *testing.T) {
+	r := NewRegistry()
+	r.Update(snapshotWithApp("app-1"))
+
+	if got := testutil.ToFloat64(r.stageTasksTotal.WithLabelValues("app-1", "Test App", "0", "0")); got != 4 {
+		t.Errorf("stageTasksTotal = %v, want 4", got)
+	}
+	if got := testutil.ToFloat64(r.stageTasksActive.WithLabelValues("app-1", "Test App", "0", "0")); got != 2 {
+		t.Errorf("stageTasksActive = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(r.jobStatus.WithLabelValues("app-1", "Test App", "0", "job-0")); got != 1 {
+		t.Errorf("jobStatus = %v, want 1 (RUNNING)", got)
+	}
+}
+
+func TestRegistryUpdateUnregistersDisappearedApp(t *testing.T) {
+	r := NewRegistry()
+	r.Update(snapshotWithApp("app-1"))
+
+	// app-1 drops out of the next snapshot entirely.
+	r.Update(poller.Snapshot{})
+
+	families, err := r.Registerer().Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "app_id" && label.GetValue() == "app-1" {
+					t.Fatalf("found stale app-1 series in %v after it disappeared", family.GetName())
+				}
+			}
+		}
+	}
+	if r.known["app-1"] {
+		t.Error("known[\"app-1\"] still true after Update with an empty snapshot")
+	}
+}
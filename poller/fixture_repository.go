@@ -0,0 +1,142 @@
+package poller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+)
+
+// fixtureRepository is a MetricDataRepository that replays a sequence of
+// canned snapshots from disk, used by tests that need to exercise the
+// poller/metrics/TUI layers without a Spark cluster. The directory is laid
+// out as a numbered sequence of generations:
+//
+//	<dir>/000/<appId>/application.json
+//	<dir>/000/<appId>/jobs.json
+//	<dir>/000/<appId>/stages.json
+//	<dir>/000/<appId>/executors.json
+//	<dir>/001/...
+//
+// Each call to Advance moves to the next generation, clamping at the last
+// one so a test can keep polling a "finished" fixture indefinitely.
+type fixtureRepository struct {
+	Dir         string
+	generations []string
+	generation  int
+}
+
+// NewFixtureRepository builds a MetricDataRepository that replays the
+// numbered generation directories found under dir, starting at the first.
+func NewFixtureRepository(dir string) (MetricDataRepository, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture directory %v: %w", dir, err)
+	}
+
+	generations := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			generations = append(generations, entry.Name())
+		}
+	}
+	sort.Strings(generations)
+
+	if len(generations) == 0 {
+		return nil, fmt.Errorf("fixture directory %v has no generation subdirectories", dir)
+	}
+
+	return &fixtureRepository{Dir: dir, generations: generations}, nil
+}
+
+func (f *fixtureRepository) current() string {
+	return filepath.Join(f.Dir, f.generations[f.generation])
+}
+
+func (f *fixtureRepository) Applications(ctx context.Context) ([]Application, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := ioutil.ReadDir(f.current())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture generation %v: %w", f.current(), err)
+	}
+
+	apps := make([]Application, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		var app Application
+		if err := readJsonFile(filepath.Join(f.current(), entry.Name(), "application.json"), &app); err != nil {
+			return nil, err
+		}
+		apps = append(apps, app)
+	}
+
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Id < apps[j].Id })
+	return apps, nil
+}
+
+func (f *fixtureRepository) Jobs(ctx context.Context, appId string) ([]Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]Job, 0)
+	if err := readJsonFile(filepath.Join(f.current(), appId, "jobs.json"), &jobs); err != nil {
+		return nil, err
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Index < jobs[j].Index })
+	return jobs, nil
+}
+
+func (f *fixtureRepository) Stages(ctx context.Context, appId string) ([]Stage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stages := make([]Stage, 0)
+	if err := readJsonFile(filepath.Join(f.current(), appId, "stages.json"), &stages); err != nil {
+		return nil, err
+	}
+	sort.Slice(stages, func(i, j int) bool { return stages[i].Index < stages[j].Index })
+	return stages, nil
+}
+
+func (f *fixtureRepository) Executors(ctx context.Context, appId string) ([]Executor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	executors := make([]Executor, 0)
+	if err := readJsonFile(filepath.Join(f.current(), appId, "executors.json"), &executors); err != nil {
+		return nil, err
+	}
+	return executors, nil
+}
+
+// Advance moves to the next generation, if any remain.
+func (f *fixtureRepository) Advance() {
+	if f.generation < len(f.generations)-1 {
+		f.generation++
+	}
+}
+
+// readJsonFile reads REST-shaped JSON from disk into out.
+func readJsonFile(path string, out interface{}) error {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %v: %w", path, err)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse %v: %w", path, err)
+	}
+
+	return nil
+}
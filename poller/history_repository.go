@@ -0,0 +1,397 @@
+package poller
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// historyRepository is a MetricDataRepository backed by a Spark History
+// Server event log directory: the newline-delimited JSON that Spark itself
+// writes under spark.eventLog.dir when spark.eventLog.enabled is set, one
+// file per application named after its application id. Each call replays
+// the relevant SparkListener* events from the app's log file and folds
+// them into the same Application/Job/Stage/Executor shapes the live REST
+// API returns, so completed applications can be browsed offline without a
+// running Spark cluster.
+//
+// A few REST fields have no single-event source in the log — most notably
+// per-executor MemoryUsed, which the live UI derives from ongoing block
+// manager updates rather than any one event — and are left at their zero
+// value rather than approximated.
+type historyRepository struct {
+	Dir string
+}
+
+// NewHistoryRepository builds a MetricDataRepository reading application
+// state from Spark History Server event logs rooted at dir.
+func NewHistoryRepository(dir string) MetricDataRepository {
+	return &historyRepository{Dir: dir}
+}
+
+func (h *historyRepository) Applications(ctx context.Context) ([]Application, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(h.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history log directory %v: %w", h.Dir, err)
+	}
+
+	apps := make([]Application, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		log, err := parseEventLog(filepath.Join(h.Dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, log.application)
+	}
+
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Id < apps[j].Id })
+	return apps, nil
+}
+
+func (h *historyRepository) Jobs(ctx context.Context, appId string) ([]Job, error) {
+	log, err := h.findLog(ctx, appId)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(log.jobs, func(i, j int) bool { return log.jobs[i].Index < log.jobs[j].Index })
+	return log.jobs, nil
+}
+
+func (h *historyRepository) Stages(ctx context.Context, appId string) ([]Stage, error) {
+	log, err := h.findLog(ctx, appId)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(log.stages, func(i, j int) bool { return log.stages[i].Index < log.stages[j].Index })
+	return log.stages, nil
+}
+
+func (h *historyRepository) Executors(ctx context.Context, appId string) ([]Executor, error) {
+	log, err := h.findLog(ctx, appId)
+	if err != nil {
+		return nil, err
+	}
+
+	executors := make([]Executor, 0, len(log.executors))
+	for _, executor := range log.executors {
+		executors = append(executors, *executor)
+	}
+	sort.Slice(executors, func(i, j int) bool { return executors[i].Id < executors[j].Id })
+	return executors, nil
+}
+
+// findLog locates and parses the event log file for appId. Event log file
+// names are the application id itself (optionally suffixed ".inprogress"
+// for a log still being written), so this is a directory scan rather than
+// a direct path join in case appId doesn't round-trip unescaped through
+// the filesystem.
+func (h *historyRepository) findLog(ctx context.Context, appId string) (*eventLog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(h.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history log directory %v: %w", h.Dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if strings.TrimSuffix(entry.Name(), ".inprogress") != appId {
+			continue
+		}
+		return parseEventLog(filepath.Join(h.Dir, entry.Name()))
+	}
+
+	return nil, fmt.Errorf("no event log for application %v in %v", appId, h.Dir)
+}
+
+// eventLog is the state accumulated by replaying one application's event
+// log file.
+type eventLog struct {
+	application Application
+	jobs        []Job
+	stages      []Stage
+	executors   map[string]*Executor
+}
+
+// rawEvent is the envelope every Spark event log line shares; the rest of
+// each line is unmarshalled based on Event.
+type rawEvent struct {
+	Event string `json:"Event"`
+}
+
+type appStartEvent struct {
+	AppName string `json:"App Name"`
+	AppID   string `json:"App ID"`
+}
+
+type jobStartEvent struct {
+	JobID    int   `json:"Job ID"`
+	StageIDs []int `json:"Stage IDs"`
+	Stages   []struct {
+		StageID   int    `json:"Stage ID"`
+		StageName string `json:"Stage Name"`
+		NumTasks  int    `json:"Number of Tasks"`
+	} `json:"Stage Infos"`
+}
+
+type jobEndEvent struct {
+	JobID     int `json:"Job ID"`
+	JobResult struct {
+		Result string `json:"Result"`
+	} `json:"Job Result"`
+}
+
+type stageInfo struct {
+	StageID        int    `json:"Stage ID"`
+	StageName      string `json:"Stage Name"`
+	NumTasks       int    `json:"Number of Tasks"`
+	SubmissionTime int64  `json:"Submission Time"`
+	CompletionTime int64  `json:"Completion Time"`
+	FailureReason  string `json:"Failure Reason"`
+}
+
+type stageSubmittedEvent struct {
+	StageInfo stageInfo `json:"Stage Info"`
+}
+
+type stageCompletedEvent struct {
+	StageInfo stageInfo `json:"Stage Info"`
+}
+
+type executorAddedEvent struct {
+	ExecutorID   string `json:"Executor ID"`
+	ExecutorInfo struct {
+		Host       string `json:"Host"`
+		TotalCores int    `json:"Total Cores"`
+	} `json:"Executor Info"`
+}
+
+type taskEndEvent struct {
+	StageID  int `json:"Stage ID"`
+	TaskInfo struct {
+		ExecutorID string `json:"Executor ID"`
+		Failed     bool   `json:"Failed"`
+		Killed     bool   `json:"Killed"`
+	} `json:"Task Info"`
+	TaskMetrics struct {
+		ExecutorRunTime    int64 `json:"Executor Run Time"`
+		JVMGCTime          int64 `json:"JVM GC Time"`
+		ShuffleReadMetrics struct {
+			RemoteBytesRead int64 `json:"Remote Bytes Read"`
+			LocalBytesRead  int64 `json:"Local Bytes Read"`
+		} `json:"Shuffle Read Metrics"`
+		ShuffleWriteMetrics struct {
+			ShuffleBytesWritten int64 `json:"Shuffle Bytes Written"`
+		} `json:"Shuffle Write Metrics"`
+	} `json:"Task Metrics"`
+}
+
+// parseEventLog replays every line of the event log at path, folding the
+// events this package cares about into an eventLog.
+func parseEventLog(path string) (*eventLog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log %v: %w", path, err)
+	}
+	defer f.Close()
+
+	log := &eventLog{
+		stages:    make([]Stage, 0),
+		jobs:      make([]Job, 0),
+		executors: make(map[string]*Executor),
+	}
+
+	stageIdx := make(map[int]int) // Stage ID -> index into log.stages
+	jobIdx := make(map[int]int)   // Job ID -> index into log.jobs
+
+	scanner := bufio.NewScanner(f)
+	// Event log lines (especially job/stage start events listing every
+	// task) can exceed bufio.Scanner's 64KiB default token size.
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var raw rawEvent
+		if err := json.Unmarshal(line, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse event log line in %v: %w", path, err)
+		}
+
+		switch raw.Event {
+		case "SparkListenerApplicationStart":
+			var e appStartEvent
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("failed to parse ApplicationStart in %v: %w", path, err)
+			}
+			log.application = Application{Id: e.AppID, Name: e.AppName}
+
+		case "SparkListenerJobStart":
+			var e jobStartEvent
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("failed to parse JobStart in %v: %w", path, err)
+			}
+			jobIdx[e.JobID] = len(log.jobs)
+			log.jobs = append(log.jobs, Job{
+				Index:  e.JobID,
+				Stages: e.StageIDs,
+				Status: "RUNNING",
+			})
+			for _, stage := range e.Stages {
+				if _, ok := stageIdx[stage.StageID]; ok {
+					continue
+				}
+				stageIdx[stage.StageID] = len(log.stages)
+				log.stages = append(log.stages, Stage{
+					Index: stage.StageID,
+					Name:  stage.StageName,
+					Tasks: stage.NumTasks,
+				})
+			}
+
+		case "SparkListenerJobEnd":
+			var e jobEndEvent
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("failed to parse JobEnd in %v: %w", path, err)
+			}
+			if idx, ok := jobIdx[e.JobID]; ok {
+				log.jobs[idx].Status = jobResultStatus(e.JobResult.Result)
+			}
+
+		case "SparkListenerStageSubmitted":
+			var e stageSubmittedEvent
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("failed to parse StageSubmitted in %v: %w", path, err)
+			}
+			idx := stageSlot(log, stageIdx, e.StageInfo.StageID)
+			log.stages[idx].Name = e.StageInfo.StageName
+			log.stages[idx].Tasks = e.StageInfo.NumTasks
+			log.stages[idx].Status = "ACTIVE"
+			if e.StageInfo.SubmissionTime > 0 {
+				log.stages[idx].SubmissionTime = formatSparkTime(e.StageInfo.SubmissionTime)
+			}
+
+		case "SparkListenerStageCompleted":
+			var e stageCompletedEvent
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("failed to parse StageCompleted in %v: %w", path, err)
+			}
+			idx := stageSlot(log, stageIdx, e.StageInfo.StageID)
+			if e.StageInfo.CompletionTime > 0 {
+				log.stages[idx].CompletionTime = formatSparkTime(e.StageInfo.CompletionTime)
+			}
+			if e.StageInfo.FailureReason != "" {
+				log.stages[idx].Status = "FAILED"
+			} else {
+				log.stages[idx].Status = "COMPLETE"
+			}
+
+		case "SparkListenerExecutorAdded":
+			var e executorAddedEvent
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("failed to parse ExecutorAdded in %v: %w", path, err)
+			}
+			log.executors[e.ExecutorID] = &Executor{
+				Id:         e.ExecutorID,
+				HostPort:   e.ExecutorInfo.Host,
+				TotalCores: e.ExecutorInfo.TotalCores,
+			}
+
+		case "SparkListenerTaskEnd":
+			var e taskEndEvent
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("failed to parse TaskEnd in %v: %w", path, err)
+			}
+
+			if idx, ok := stageIdx[e.StageID]; ok {
+				switch {
+				case e.TaskInfo.Failed:
+					log.stages[idx].FailedTasks++
+				case e.TaskInfo.Killed:
+					log.stages[idx].KilledTasks++
+				default:
+					log.stages[idx].CompletedTasks++
+				}
+			}
+
+			executor, ok := log.executors[e.TaskInfo.ExecutorID]
+			if !ok {
+				executor = &Executor{Id: e.TaskInfo.ExecutorID}
+				log.executors[e.TaskInfo.ExecutorID] = executor
+			}
+			executor.TotalTasks++
+			if e.TaskInfo.Failed {
+				executor.FailedTasks++
+			} else if !e.TaskInfo.Killed {
+				executor.CompletedTasks++
+			}
+			executor.TotalDuration += e.TaskMetrics.ExecutorRunTime
+			executor.TotalGCTime += e.TaskMetrics.JVMGCTime
+			executor.TotalShuffleRead += e.TaskMetrics.ShuffleReadMetrics.RemoteBytesRead + e.TaskMetrics.ShuffleReadMetrics.LocalBytesRead
+			executor.TotalShuffleWrite += e.TaskMetrics.ShuffleWriteMetrics.ShuffleBytesWritten
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan event log %v: %w", path, err)
+	}
+
+	if log.application.Id == "" {
+		log.application.Id = strings.TrimSuffix(filepath.Base(path), ".inprogress")
+	}
+
+	return log, nil
+}
+
+// stageSlot returns the index of stageId within log.stages, appending a
+// blank Stage and recording it in stageIdx if this is the first event
+// mentioning that stage.
+func stageSlot(log *eventLog, stageIdx map[int]int, stageId int) int {
+	if idx, ok := stageIdx[stageId]; ok {
+		return idx
+	}
+	idx := len(log.stages)
+	stageIdx[stageId] = idx
+	log.stages = append(log.stages, Stage{Index: stageId})
+	return idx
+}
+
+// jobResultStatus maps a SparkListenerJobEnd "Job Result" to the same
+// status vocabulary the live REST API uses.
+func jobResultStatus(result string) string {
+	switch result {
+	case "JobSucceeded":
+		return "SUCCEEDED"
+	case "JobFailed":
+		return "FAILED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// formatSparkTime renders an event log epoch-millisecond timestamp in the
+// layout Stage.Duration expects, matching what the live REST API emits for
+// submissionTime/completionTime.
+func formatSparkTime(epochMillis int64) string {
+	return time.UnixMilli(epochMillis).UTC().Format(sparkTimeLayout)
+}
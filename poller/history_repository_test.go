@@ -0,0 +1,65 @@
+package poller
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHistoryRepositoryReplaysEventLog(t *testing.T) {
+	repo := NewHistoryRepository("testdata/eventlogs")
+	ctx := context.Background()
+	const appId = "app-20260727100000-0001"
+
+	apps, err := repo.Applications(ctx)
+	if err != nil {
+		t.Fatalf("Applications: %v", err)
+	}
+	if len(apps) != 1 || apps[0].Id != appId || apps[0].Name != "Example App" {
+		t.Fatalf("Applications() = %+v, want one app %v", apps, appId)
+	}
+
+	jobs, err := repo.Jobs(ctx, appId)
+	if err != nil {
+		t.Fatalf("Jobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Status != "SUCCEEDED" {
+		t.Fatalf("Jobs() = %+v, want one SUCCEEDED job", jobs)
+	}
+
+	stages, err := repo.Stages(ctx, appId)
+	if err != nil {
+		t.Fatalf("Stages: %v", err)
+	}
+	if len(stages) != 1 {
+		t.Fatalf("Stages() = %+v, want one stage", stages)
+	}
+	stage := stages[0]
+	if stage.CompletedTasks != 1 || stage.FailedTasks != 1 {
+		t.Errorf("stage task counts = completed:%d failed:%d, want 1/1", stage.CompletedTasks, stage.FailedTasks)
+	}
+	duration, ok := stage.Duration()
+	if !ok {
+		t.Fatal("stage.Duration() ok = false, want true")
+	}
+	if duration.Seconds() != 2.5 {
+		t.Errorf("stage.Duration() = %v, want 2.5s", duration)
+	}
+
+	executors, err := repo.Executors(ctx, appId)
+	if err != nil {
+		t.Fatalf("Executors: %v", err)
+	}
+	if len(executors) != 1 {
+		t.Fatalf("Executors() = %+v, want one executor", executors)
+	}
+	executor := executors[0]
+	if executor.HostPort != "worker-1" || executor.TotalCores != 4 {
+		t.Errorf("executor = %+v, want host worker-1 with 4 cores", executor)
+	}
+	if executor.CompletedTasks != 1 || executor.FailedTasks != 1 {
+		t.Errorf("executor task counts = completed:%d failed:%d, want 1/1", executor.CompletedTasks, executor.FailedTasks)
+	}
+	if executor.TotalDuration != 700 || executor.TotalGCTime != 15 {
+		t.Errorf("executor duration/gc = %d/%d, want 700/15", executor.TotalDuration, executor.TotalGCTime)
+	}
+}
@@ -0,0 +1,277 @@
+// Package poller fetches application/job/stage state from a
+// MetricDataRepository and assembles it into a Snapshot that renderers
+// (TUI, metrics) can consume.
+package poller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency is used when Client.Concurrency is unset.
+const DefaultConcurrency = 8
+
+// DefaultRequestTimeout bounds how long Poll waits on any single
+// application's jobs/stages/executors before giving up on it.
+const DefaultRequestTimeout = 10 * time.Second
+
+// Client polls a MetricDataRepository for application state.
+type Client struct {
+	Repo MetricDataRepository
+
+	// Source is a human-readable label for where Repo's data comes from
+	// (a host URL, an archive path, ...), shown in the TUI header.
+	Source string
+
+	// Concurrency bounds how many applications are fetched in parallel.
+	// Zero means DefaultConcurrency.
+	Concurrency int
+
+	// RequestTimeout bounds each application's fetch. Zero means
+	// DefaultRequestTimeout.
+	RequestTimeout time.Duration
+}
+
+// NewClient returns a Client that polls repo, labelling its origin as
+// source for display purposes.
+func NewClient(repo MetricDataRepository, source string) *Client {
+	return &Client{
+		Repo:   repo,
+		Source: source,
+	}
+}
+
+// appResult is one worker's outcome for a single application, tagged with
+// its original index so results can be reassembled in Applications() order
+// despite being produced out of order.
+type appResult struct {
+	index    int
+	enriched EnrichedApplication
+	err      error
+}
+
+// Poll fetches all applications and, for each, its jobs/stages/executors,
+// returning a fully enriched Snapshot. Applications are fanned out across a
+// pool of Concurrency workers so latency no longer scales linearly with the
+// number of running applications. If Repo is Replayable, it is advanced to
+// its next generation afterwards.
+func (c *Client) Poll() (Snapshot, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	apps, err := c.Repo.Applications(ctx)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to list applications: %w", err)
+	}
+
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	if concurrency > len(apps) {
+		concurrency = len(apps)
+	}
+
+	appsEnriched := make([]EnrichedApplication, len(apps))
+
+	if concurrency > 0 {
+		work := make(chan int)
+		results := make(chan appResult)
+
+		var workers sync.WaitGroup
+		workers.Add(concurrency)
+		for w := 0; w < concurrency; w++ {
+			go func() {
+				defer workers.Done()
+				for i := range work {
+					enriched, err := c.fetchApp(ctx, apps[i])
+					results <- appResult{index: i, enriched: enriched, err: err}
+				}
+			}()
+		}
+
+		go func() {
+			for i := range apps {
+				work <- i
+			}
+			close(work)
+		}()
+
+		go func() {
+			workers.Wait()
+			close(results)
+		}()
+
+		var firstErr error
+		for res := range results {
+			if res.err != nil {
+				if firstErr == nil {
+					firstErr = res.err
+					// Stop other in-flight fetches promptly once we know
+					// Poll is going to fail, rather than waiting out their
+					// full RequestTimeout.
+					cancel()
+				}
+				continue
+			}
+			appsEnriched[res.index] = res.enriched
+		}
+		if firstErr != nil {
+			return Snapshot{}, firstErr
+		}
+	}
+
+	if replayable, ok := c.Repo.(Replayable); ok {
+		replayable.Advance()
+	}
+
+	return Snapshot{
+		Apps: appsEnriched,
+		Host: c.Source,
+	}, nil
+}
+
+// fetchApp fetches and assembles one application's jobs, stages and
+// executors under a per-request deadline.
+func (c *Client) fetchApp(parent context.Context, app Application) (EnrichedApplication, error) {
+	timeout := c.RequestTimeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	jobs, err := c.Repo.Jobs(ctx, app.Id)
+	if err != nil {
+		return EnrichedApplication{}, fmt.Errorf("failed to list jobs for app %v: %w", app.Id, err)
+	}
+
+	stages, err := c.Repo.Stages(ctx, app.Id)
+	if err != nil {
+		return EnrichedApplication{}, fmt.Errorf("failed to list stages for app %v: %w", app.Id, err)
+	}
+
+	executors, err := c.Repo.Executors(ctx, app.Id)
+	if err != nil {
+		return EnrichedApplication{}, fmt.Errorf("failed to list executors for app %v: %w", app.Id, err)
+	}
+
+	stagesMap := make(map[int]Stage)
+	for _, stage := range stages {
+		stagesMap[stage.Index] = stage
+	}
+
+	jobsEnriched := make([]EnrichedJob, len(jobs))
+	for j, job := range jobs {
+		jobStages := make([]Stage, len(job.Stages))
+		for k, jobStage := range job.Stages {
+			jobStages[k] = stagesMap[jobStage]
+		}
+
+		jobsEnriched[j] = EnrichedJob{
+			Job:    job,
+			Stages: jobStages,
+		}
+	}
+
+	return EnrichedApplication{
+		App:       app,
+		Jobs:      jobsEnriched,
+		Executors: executors,
+	}, nil
+}
+
+// Application identifies a single Spark application.
+type Application struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Job describes a Spark job within an application.
+type Job struct {
+	Index  int    `json:"jobId"`
+	Name   string `json:"name"`
+	Stages []int  `json:"stageIds"`
+	Status string `json:"status"`
+}
+
+// Stage describes a single stage of a job.
+type Stage struct {
+	Index          int    `json:"stageId"`
+	Name           string `json:"name"`
+	Details        string `json:"details"`
+	Status         string `json:"status"`
+	Tasks          int    `json:"numTasks"`
+	ActiveTasks    int    `json:"numActiveTasks"`
+	CompletedTasks int    `json:"numCompleteTasks"`
+	FailedTasks    int    `json:"numFailedTasks"`
+	KilledTasks    int    `json:"numKilledTasks"`
+	SubmissionTime string `json:"submissionTime"`
+	CompletionTime string `json:"completionTime"`
+}
+
+// sparkTimeLayout matches the timestamps Spark's REST API emits for
+// submissionTime/completionTime, e.g. "2016-05-06T13:03:00.647GMT".
+const sparkTimeLayout = "2006-01-02T15:04:05.000MST"
+
+// Duration returns how long the stage ran, if it has both started and
+// completed. The second return value is false if either timestamp is
+// missing or unparseable (e.g. the stage hasn't completed yet).
+func (s Stage) Duration() (time.Duration, bool) {
+	if s.SubmissionTime == "" || s.CompletionTime == "" {
+		return 0, false
+	}
+
+	start, err := time.Parse(sparkTimeLayout, s.SubmissionTime)
+	if err != nil {
+		return 0, false
+	}
+
+	end, err := time.Parse(sparkTimeLayout, s.CompletionTime)
+	if err != nil {
+		return 0, false
+	}
+
+	return end.Sub(start), true
+}
+
+// Executor describes a single executor's resource usage, as reported by
+// /api/v1/applications/{id}/executors.
+type Executor struct {
+	Id                string `json:"id"`
+	HostPort          string `json:"hostPort"`
+	TotalCores        int    `json:"totalCores"`
+	ActiveTasks       int    `json:"activeTasks"`
+	CompletedTasks    int    `json:"completedTasks"`
+	FailedTasks       int    `json:"failedTasks"`
+	TotalTasks        int    `json:"totalTasks"`
+	TotalDuration     int64  `json:"totalDuration"`
+	TotalGCTime       int64  `json:"totalGCTime"`
+	MemoryUsed        int64  `json:"memoryUsed"`
+	MaxMemory         int64  `json:"maxMemory"`
+	TotalShuffleRead  int64  `json:"totalShuffleRead"`
+	TotalShuffleWrite int64  `json:"totalShuffleWrite"`
+}
+
+// Snapshot is a point-in-time view of every known application, enriched
+// with its jobs and their stages.
+type Snapshot struct {
+	Apps []EnrichedApplication
+	Host string
+}
+
+// EnrichedApplication pairs an Application with its enriched jobs and
+// current executors.
+type EnrichedApplication struct {
+	App       Application
+	Jobs      []EnrichedJob
+	Executors []Executor
+}
+
+// EnrichedJob pairs a Job with its resolved Stage values.
+type EnrichedJob struct {
+	Job    Job
+	Stages []Stage
+}
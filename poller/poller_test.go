@@ -0,0 +1,99 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestClientPollFansOutAcrossFixtureApps(t *testing.T) {
+	repo, err := NewFixtureRepository("testdata/fixture")
+	if err != nil {
+		t.Fatalf("NewFixtureRepository: %v", err)
+	}
+
+	client := NewClient(repo, "testdata/fixture")
+	client.Concurrency = 1 // exercise the worker pool with more apps than workers
+
+	snapshot, err := client.Poll()
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	if len(snapshot.Apps) != 2 {
+		t.Fatalf("got %d apps, want 2", len(snapshot.Apps))
+	}
+
+	byId := make(map[string]EnrichedApplication, len(snapshot.Apps))
+	for _, app := range snapshot.Apps {
+		byId[app.App.Id] = app
+	}
+
+	app1, ok := byId["app-1"]
+	if !ok {
+		t.Fatalf("missing app-1 in snapshot: %+v", snapshot.Apps)
+	}
+	if len(app1.Jobs) != 1 || len(app1.Jobs[0].Stages) != 1 {
+		t.Fatalf("app-1 jobs not enriched correctly: %+v", app1.Jobs)
+	}
+	if app1.Jobs[0].Stages[0].Tasks != 4 {
+		t.Errorf("app-1 stage 0 Tasks = %d, want 4", app1.Jobs[0].Stages[0].Tasks)
+	}
+
+	app2, ok := byId["app-2"]
+	if !ok {
+		t.Fatalf("missing app-2 in snapshot: %+v", snapshot.Apps)
+	}
+	if len(app2.Jobs) != 1 || len(app2.Jobs[0].Stages) != 2 {
+		t.Fatalf("app-2 jobs not enriched correctly: %+v", app2.Jobs)
+	}
+	gotStageIds := []int{app2.Jobs[0].Stages[0].Index, app2.Jobs[0].Stages[1].Index}
+	sort.Ints(gotStageIds)
+	if gotStageIds[0] != 0 || gotStageIds[1] != 1 {
+		t.Errorf("app-2 resolved stage ids = %v, want [0 1]", gotStageIds)
+	}
+}
+
+// failingRepository returns apps normally but fails every Jobs call for one
+// app id, so Poll's fan-in error handling can be exercised without needing
+// a real HTTP server.
+type failingRepository struct {
+	apps    []Application
+	failApp string
+}
+
+func (f *failingRepository) Applications(ctx context.Context) ([]Application, error) {
+	return f.apps, nil
+}
+
+func (f *failingRepository) Jobs(ctx context.Context, appId string) ([]Job, error) {
+	if appId == f.failApp {
+		return nil, errors.New("boom")
+	}
+	return nil, nil
+}
+
+func (f *failingRepository) Stages(ctx context.Context, appId string) ([]Stage, error) {
+	return nil, nil
+}
+
+func (f *failingRepository) Executors(ctx context.Context, appId string) ([]Executor, error) {
+	return nil, nil
+}
+
+func TestClientPollPropagatesPerAppError(t *testing.T) {
+	repo := &failingRepository{
+		apps: []Application{
+			{Id: "good-1"}, {Id: "good-2"}, {Id: "bad"}, {Id: "good-3"},
+		},
+		failApp: "bad",
+	}
+
+	client := NewClient(repo, "failing")
+	client.Concurrency = 2
+
+	if _, err := client.Poll(); err == nil {
+		t.Fatal("Poll() with a failing app returned nil error")
+	}
+}
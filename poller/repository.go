@@ -0,0 +1,25 @@
+package poller
+
+import "context"
+
+// MetricDataRepository abstracts where application/job/stage state comes
+// from, so a Client can poll a live Spark REST API, a Spark History Server
+// event log directory, or a replayable JSON fixture directory identically.
+// Every method takes a context so Client can bound how long it waits on any
+// one application while fanning out across many.
+//
+// Implementations: restRepository (live API), historyRepository (on-disk
+// Spark event logs), fixtureRepository (canned JSON for tests).
+type MetricDataRepository interface {
+	Applications(ctx context.Context) ([]Application, error)
+	Jobs(ctx context.Context, appId string) ([]Job, error)
+	Stages(ctx context.Context, appId string) ([]Stage, error)
+	Executors(ctx context.Context, appId string) ([]Executor, error)
+}
+
+// Replayable is implemented by repositories that advance through a sequence
+// of canned snapshots, one per Poll call, rather than reflecting a single
+// live state. fixtureRepository is the only current implementation.
+type Replayable interface {
+	Advance()
+}
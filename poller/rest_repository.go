@@ -0,0 +1,183 @@
+package poller
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const defaultTimeout = 10 * time.Second
+const defaultUserAgent = "sparkmon"
+
+// RestRepositoryOptions configures the http.Client used by a
+// restRepository: request timeout, identification, optional auth for Spark
+// UIs sitting behind a proxy, and TLS verification.
+type RestRepositoryOptions struct {
+	Timeout       time.Duration
+	UserAgent     string
+	BasicAuthUser string
+	BasicAuthPass string
+	BearerToken   string
+	TLSSkipVerify bool
+}
+
+// restRepository is a MetricDataRepository backed by a live Spark REST API.
+type restRepository struct {
+	Host       string
+	HTTPClient *http.Client
+}
+
+// NewRestRepository builds a MetricDataRepository that polls the live Spark
+// REST API at host (e.g. "http://localhost:4040") using opts to configure
+// the underlying http.Client.
+func NewRestRepository(host string, opts RestRepositoryOptions) MetricDataRepository {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+
+	transport := &http.Transport{}
+	if opts.TLSSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return &restRepository{
+		Host: host,
+		HTTPClient: &http.Client{
+			Timeout: timeout,
+			Transport: &authTransport{
+				Base:          transport,
+				UserAgent:     userAgent,
+				BasicAuthUser: opts.BasicAuthUser,
+				BasicAuthPass: opts.BasicAuthPass,
+				BearerToken:   opts.BearerToken,
+			},
+		},
+	}
+}
+
+// authTransport decorates outgoing requests with a User-Agent and, if
+// configured, basic-auth or bearer-token credentials, before delegating to
+// Base.
+type authTransport struct {
+	Base          http.RoundTripper
+	UserAgent     string
+	BasicAuthUser string
+	BasicAuthPass string
+	BearerToken   string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.UserAgent)
+
+	if t.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+t.BearerToken)
+	} else if t.BasicAuthUser != "" {
+		req.SetBasicAuth(t.BasicAuthUser, t.BasicAuthPass)
+	}
+
+	return t.Base.RoundTrip(req)
+}
+
+// AuthError is returned when the Spark REST API (or a proxy in front of it)
+// rejects a request with HTTP 401/403, the expected failure mode for a
+// missing or incorrect BasicAuthUser/BasicAuthPass or BearerToken.
+type AuthError struct {
+	Endpoint   string
+	StatusCode int
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("authentication rejected for %v: HTTP %v", e.Endpoint, e.StatusCode)
+}
+
+// HTTPStatusError is returned when the Spark REST API responds with any
+// other non-200 status, e.g. a proxy in front of it returning an HTML
+// login page or a 5xx from an overloaded master.
+type HTTPStatusError struct {
+	Endpoint   string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status for %v: %v", e.Endpoint, e.StatusCode)
+}
+
+func (r *restRepository) Applications(ctx context.Context) ([]Application, error) {
+	apps := make([]Application, 0)
+	if err := r.readApiEndpoint(ctx, "/api/v1/applications", &apps); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+func (r *restRepository) Jobs(ctx context.Context, appId string) ([]Job, error) {
+	jobs := make([]Job, 0)
+	endpoint := fmt.Sprintf("/api/v1/applications/%v/jobs", appId)
+	if err := r.readApiEndpoint(ctx, endpoint, &jobs); err != nil {
+		return nil, err
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Index < jobs[j].Index })
+	return jobs, nil
+}
+
+func (r *restRepository) Stages(ctx context.Context, appId string) ([]Stage, error) {
+	stages := make([]Stage, 0)
+	endpoint := fmt.Sprintf("/api/v1/applications/%v/stages", appId)
+	if err := r.readApiEndpoint(ctx, endpoint, &stages); err != nil {
+		return nil, err
+	}
+	sort.Slice(stages, func(i, j int) bool { return stages[i].Index < stages[j].Index })
+	return stages, nil
+}
+
+func (r *restRepository) Executors(ctx context.Context, appId string) ([]Executor, error) {
+	executors := make([]Executor, 0)
+	endpoint := fmt.Sprintf("/api/v1/applications/%v/executors", appId)
+	if err := r.readApiEndpoint(ctx, endpoint, &executors); err != nil {
+		return nil, err
+	}
+	return executors, nil
+}
+
+func (r *restRepository) readApiEndpoint(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.Host+endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request for %v: %w", endpoint, err)
+	}
+
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to GET endpoint %v: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return &AuthError{Endpoint: endpoint, StatusCode: resp.StatusCode}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &HTTPStatusError{Endpoint: endpoint, StatusCode: resp.StatusCode}
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body for %v: %w", endpoint, err)
+	}
+
+	if err := json.Unmarshal(body, &out); err != nil {
+		return fmt.Errorf("failed to parse response for %v: %w", endpoint, err)
+	}
+
+	return nil
+}
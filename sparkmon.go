@@ -1,36 +1,139 @@
 package main
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
-	"io/ioutil"
 	"log"
 	"math"
 	"net/http"
-	"os"
-	"sort"
 	"strings"
 	"time"
+
+	"github.com/Emberwalker/sparkmon/history"
+	"github.com/Emberwalker/sparkmon/metrics"
+	"github.com/Emberwalker/sparkmon/poller"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const pollInterval = 5 * time.Second
+const baseRetryDelay = 1 * time.Second
+const maxRetryDelay = 32 * time.Second
+
+// Metric names recorded into the history.Store for the executors page.
+const (
+	metricCPUTime      = "cpu_time_ms"
+	metricMemoryUsed   = "memory_used_bytes"
+	metricShuffleRead  = "shuffle_read_bytes"
+	metricShuffleWrite = "shuffle_write_bytes"
+	metricGCTime       = "gc_time_ms"
+	metricTasksDone    = "tasks_completed"
 )
 
 func main() {
+	prometheusAddr := flag.String("prometheus-addr", "", "if set, serve Prometheus metrics on this address (e.g. :9090) alongside the TUI")
+	backend := flag.String("backend", "rest", "data source to poll: \"rest\" (live Spark REST API), \"history\" (extracted History Server archive directory), or \"fixture\" (replayable JSON fixture directory, for tests)")
+	timeout := flag.Duration("timeout", 0, "HTTP request timeout for the rest backend (default 10s)")
+	userAgent := flag.String("user-agent", "", "custom User-Agent header for the rest backend")
+	basicAuthUser := flag.String("basic-auth-user", "", "basic-auth username for the rest backend, for Spark UIs behind a proxy")
+	basicAuthPass := flag.String("basic-auth-pass", "", "basic-auth password for the rest backend")
+	bearerToken := flag.String("bearer-token", "", "bearer token for the rest backend, for Spark UIs behind a proxy")
+	tlsSkipVerify := flag.Bool("tls-skip-verify", false, "skip TLS certificate verification for the rest backend")
+	historyWindow := flag.Duration("history-window", 10*time.Minute, "how long to retain per-executor samples for the executors page sparklines")
+	fetchConcurrency := flag.Int("fetch-concurrency", poller.DefaultConcurrency, "number of applications to fetch jobs/stages/executors for in parallel")
+	flag.Parse()
+
+	source := "http://localhost:4040"
+	if flag.NArg() > 0 {
+		source = flag.Arg(0)
+	}
+
+	repo, err := buildRepository(*backend, source, poller.RestRepositoryOptions{
+		Timeout:       *timeout,
+		UserAgent:     *userAgent,
+		BasicAuthUser: *basicAuthUser,
+		BasicAuthPass: *basicAuthPass,
+		BearerToken:   *bearerToken,
+		TLSSkipVerify: *tlsSkipVerify,
+	})
+	if err != nil {
+		log.Fatalf("failed to initialize %v backend: %v", *backend, err)
+	}
+
+	client := poller.NewClient(repo, source)
+	client.Concurrency = *fetchConcurrency
+	// fetchApp issues three sequential requests per app (jobs, stages,
+	// executors) under a single deadline, so give it budget for all three
+	// rather than reusing the per-HTTP-request timeout directly — otherwise
+	// a small --timeout aborts legitimately-slow apps before any individual
+	// request has even timed out.
+	if *timeout > 0 {
+		client.RequestTimeout = 3 * *timeout
+	}
+	hist := history.NewStore(*historyWindow)
+
+	var reg *metrics.Registry
+	if *prometheusAddr != "" {
+		reg = metrics.NewRegistry()
+		go serveMetrics(*prometheusAddr, reg)
+	}
+
 	if err := ui.Init(); err != nil {
 		log.Fatalf("failed to initialize termui: %v", err)
 	}
 	defer ui.Close()
 
-	host := "http://localhost:4040"
-	if len(os.Args) > 1 {
-		host = os.Args[1]
-	}
+	state := poller.Snapshot{Host: source}
+	status := ""
+	retryDelay := time.Duration(0)
+	showExecutors := false
 
-	state := computeState(host)
 	termWidth, termHeight := ui.TerminalDimensions()
-	render(termWidth, termHeight, state)
 
-	ticker := time.Tick(5 * time.Second)
+	renderCurrent := func() {
+		if showExecutors {
+			renderExecutors(termWidth, termHeight, state, hist)
+		} else {
+			render(termWidth, termHeight, state, status)
+		}
+	}
+	renderCurrent()
+
+	// pollTimer fires immediately for the first poll, then is rescheduled
+	// after every poll: at pollInterval on success, or at an exponentially
+	// increasing retryDelay on failure, so a transient Spark outage degrades
+	// to slower polling instead of killing the monitor.
+	pollTimer := time.NewTimer(0)
+	defer pollTimer.Stop()
+
+	poll := func() {
+		newState, err := client.Poll()
+		if err != nil {
+			if retryDelay == 0 {
+				retryDelay = baseRetryDelay
+			} else {
+				retryDelay *= 2
+			}
+			if retryDelay > maxRetryDelay {
+				retryDelay = maxRetryDelay
+			}
+			status = fmt.Sprintf("Spark unreachable — retrying in %v (%v)", retryDelay, err)
+			log.Printf("poll failed: %v", err)
+			pollTimer.Reset(retryDelay)
+			return
+		}
+
+		retryDelay = 0
+		status = ""
+		recordExecutorHistory(hist, state, newState)
+		state = newState
+		if reg != nil {
+			reg.Update(state)
+		}
+		pollTimer.Reset(pollInterval)
+	}
+
 	uiEvents := ui.PollEvents()
 
 	for {
@@ -38,24 +141,84 @@ func main() {
 		case e := <-uiEvents:
 			switch e.ID {
 			case "r":
-				state = computeState(host)
-				render(termWidth, termHeight, state)
+				poll()
+				renderCurrent()
+			case "e":
+				showExecutors = !showExecutors
+				renderCurrent()
 			case "q", "<C-c>":
 				return
 			case "<Resize>":
 				payload := e.Payload.(ui.Resize)
 				termWidth = payload.Width
 				termHeight = payload.Height
-				render(termWidth, termHeight, state)
+				renderCurrent()
+			}
+		case <-pollTimer.C:
+			poll()
+			renderCurrent()
+		}
+	}
+}
+
+// recordExecutorHistory feeds every executor's current resource metrics
+// into hist, so the executors page can render their recent trend as
+// sparklines. Apps present in previous but absent from current have their
+// series forgotten, so finished applications don't linger in memory
+// forever.
+func recordExecutorHistory(hist *history.Store, previous, current poller.Snapshot) {
+	seen := make(map[string]bool, len(current.Apps))
+
+	for _, app := range current.Apps {
+		seen[app.App.Id] = true
+
+		for _, executor := range app.Executors {
+			key := func(metric string) history.Key {
+				return history.Key{AppId: app.App.Id, ExecutorId: executor.Id, Metric: metric}
 			}
-		case <-ticker:
-			state = computeState(host)
-			render(termWidth, termHeight, state)
+			hist.Record(key(metricCPUTime), float64(executor.TotalDuration))
+			hist.Record(key(metricMemoryUsed), float64(executor.MemoryUsed))
+			hist.Record(key(metricShuffleRead), float64(executor.TotalShuffleRead))
+			hist.Record(key(metricShuffleWrite), float64(executor.TotalShuffleWrite))
+			hist.Record(key(metricGCTime), float64(executor.TotalGCTime))
+			hist.Record(key(metricTasksDone), float64(executor.CompletedTasks))
+		}
+	}
+
+	for _, app := range previous.Apps {
+		if !seen[app.App.Id] {
+			hist.ForgetApp(app.App.Id)
 		}
 	}
 }
 
-func render(width, height int, state State) {
+// buildRepository constructs the MetricDataRepository named by backend,
+// treating source as a host URL for "rest" or a directory path for
+// "history"/"fixture". restOpts is only used for the "rest" backend.
+func buildRepository(backend, source string, restOpts poller.RestRepositoryOptions) (poller.MetricDataRepository, error) {
+	switch backend {
+	case "rest":
+		return poller.NewRestRepository(source, restOpts), nil
+	case "history":
+		return poller.NewHistoryRepository(source), nil
+	case "fixture":
+		return poller.NewFixtureRepository(source)
+	default:
+		return nil, fmt.Errorf("unknown backend %q (want \"rest\", \"history\" or \"fixture\")", backend)
+	}
+}
+
+// serveMetrics runs a Prometheus exporter HTTP server on addr until the
+// process exits.
+func serveMetrics(addr string, reg *metrics.Registry) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg.Registerer(), promhttp.HandlerOpts{}))
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("prometheus exporter failed: %v", err)
+	}
+}
+
+func render(width, height int, state poller.Snapshot, status string) {
 	drawables := make([]ui.Drawable, 0)
 	currentHeight := 0
 
@@ -67,7 +230,19 @@ func render(width, height int, state State) {
 	clusterInfo.SetRect(0, 0, width, currentHeight+1)
 	drawables = append(drawables, clusterInfo)
 
-	currentHeight += 2
+	currentHeight += 1
+
+	if status != "" {
+		statusLine := widgets.NewParagraph()
+		statusLine.Border = false
+		statusLine.TextStyle.Fg = ui.ColorRed
+		statusLine.Text = status
+		statusLine.SetRect(0, currentHeight, width, currentHeight+1)
+		drawables = append(drawables, statusLine)
+		currentHeight += 1
+	}
+
+	currentHeight += 1
 
 	for _, app := range state.Apps {
 		// Used for working out length of labels vs progress bars
@@ -151,117 +326,55 @@ func render(width, height int, state State) {
 	ui.Render(drawables...)
 }
 
-func computeState(host string) State {
-	apps := getApplications(host)
-	appsEnriched := make([]EnrichedApplication, len(apps))
-	for i, app := range apps {
-		jobs := getJobs(host, app)
-		stages := getStages(host, app)
-		stagesMap := make(map[int]Stage)
-		for _, stage := range stages {
-			stagesMap[stage.Index] = stage
-		}
-
-		jobsEnriched := make([]EnrichedJob, len(jobs))
-		for j, job := range jobs {
-			jobStages := make([]Stage, len(job.Stages))
-			for k, jobStage := range job.Stages {
-				jobStages[k] = stagesMap[jobStage]
-			}
-
-			jobsEnriched[j] = EnrichedJob{
-				Job:    job,
-				Stages: jobStages,
-			}
-		}
-
-		appsEnriched[i] = EnrichedApplication{
-			App:  app,
-			Jobs: jobsEnriched,
-		}
-	}
-
-	return State{
-		Apps: appsEnriched,
-		Host: host,
-	}
-}
+// renderExecutors draws the executors page: one SparklineGroup per
+// executor, showing CPU time, memory used, shuffle read/write, GC time and
+// completed tasks over the retained history window. Toggled with 'e'.
+func renderExecutors(width, height int, state poller.Snapshot, hist *history.Store) {
+	drawables := make([]ui.Drawable, 0)
+	currentHeight := 0
 
-func getApplications(host string) []ApplicationIdAndName {
-	apps := make([]ApplicationIdAndName, 0)
-	readApiEndpoint(host, "/api/v1/applications", &apps)
-	return apps
-}
+	header := widgets.NewParagraph()
+	header.Border = false
+	header.TextStyle.Modifier = ui.ModifierBold
+	header.Text = fmt.Sprintf("Executors on %v (press 'e' to return)", state.Host)
+	header.SetRect(0, 0, width, 1)
+	drawables = append(drawables, header)
+	currentHeight += 2
 
-func getJobs(host string, app ApplicationIdAndName) []Job {
-	jobs := make([]Job, 0)
-	endpoint := fmt.Sprintf("/api/v1/applications/%v/jobs", app.Id)
-	readApiEndpoint(host, endpoint, &jobs)
-	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Index < jobs[j].Index })
-	return jobs
-}
+	const groupHeight = 8
 
-func getStages(host string, app ApplicationIdAndName) []Stage {
-	stages := make([]Stage, 0)
-	endpoint := fmt.Sprintf("/api/v1/applications/%v/stages", app.Id)
-	readApiEndpoint(host, endpoint, &stages)
-	sort.Slice(stages, func(i, j int) bool { return stages[i].Index < stages[j].Index })
-	return stages
-}
+	for _, app := range state.Apps {
+		for _, executor := range app.Executors {
+			key := func(metric string) history.Key {
+				return history.Key{AppId: app.App.Id, ExecutorId: executor.Id, Metric: metric}
+			}
 
-func readApiEndpoint(host string, endpoint string, out interface{}) {
-	resp, err := http.Get(host + endpoint)
-	if err != nil {
-		log.Fatalf("Failed to GET endpoint %v: %v", endpoint, err)
-	}
-	defer resp.Body.Close()
+			sparklines := []*widgets.Sparkline{
+				namedSparkline("CPU time (ms)", hist.Series(key(metricCPUTime))),
+				namedSparkline(fmt.Sprintf("Memory used / %v", executor.MaxMemory), hist.Series(key(metricMemoryUsed))),
+				namedSparkline("Shuffle read (bytes)", hist.Series(key(metricShuffleRead))),
+				namedSparkline("Shuffle write (bytes)", hist.Series(key(metricShuffleWrite))),
+				namedSparkline("GC time (ms)", hist.Series(key(metricGCTime))),
+				namedSparkline("Tasks completed", hist.Series(key(metricTasksDone))),
+			}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalf("Failed to read body for %v: %v", endpoint, err)
-	}
+			group := widgets.NewSparklineGroup(sparklines...)
+			group.Title = fmt.Sprintf("%v executor %v (%v)", app.App.Name, executor.Id, executor.HostPort)
+			group.SetRect(0, currentHeight, width, currentHeight+groupHeight)
+			drawables = append(drawables, group)
 
-	err = json.Unmarshal(body, &out)
-	if err != nil {
-		log.Fatalf("Failed to parse applications response for %v: %v", endpoint, err)
+			currentHeight += groupHeight
+		}
 	}
-}
 
-type ApplicationIdAndName struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
-}
-
-type Job struct {
-	Index  int    `json:"jobId"`
-	Name   string `json:"name"`
-	Stages []int  `json:"stageIds"`
-	Status string `json:"status"`
-}
-
-type Stage struct {
-	Index          int    `json:"stageId"`
-	Name           string `json:"name"`
-	Details        string `json:"details"`
-	Status         string `json:"status"`
-	Tasks          int    `json:"numTasks"`
-	ActiveTasks    int    `json:"numActiveTasks"`
-	CompletedTasks int    `json:"numCompleteTasks"`
-	FailedTasks    int    `json:"numFailedTasks"`
-	KilledTasks    int    `json:"numKilledTasks"`
-}
-
-type State struct {
-	Apps []EnrichedApplication
-	Host string
-}
-
-type EnrichedApplication struct {
-	App  ApplicationIdAndName
-	Jobs []EnrichedJob
+	ui.Clear()
+	ui.Render(drawables...)
 }
 
-type EnrichedJob struct {
-	Job    Job
-	Stages []Stage
+func namedSparkline(title string, data []float64) *widgets.Sparkline {
+	sl := widgets.NewSparkline()
+	sl.Title = title
+	sl.Data = data
+	sl.LineColor = ui.ColorGreen
+	return sl
 }